@@ -13,13 +13,11 @@ import (
 	"time"
 
 	"github.com/crosstalkio/log"
-	"google.golang.org/protobuf/proto"
 )
 
 const (
 	contentTypeHeader = "Content-Type"
 	jsonContentType   = "application/json"
-	protoContentType  = "application/protobuf"
 )
 
 type Auth interface {
@@ -73,25 +71,18 @@ func (r *Request) Delete() (*Response, error) {
 type Response struct {
 	log.Sugar
 	*http.Response
-	Body     []byte
-	protobuf bool
+	Body   []byte
+	codecs *CodecRegistry
 }
 
 func (r *Response) Decode(val interface{}) error {
-	var err error
-	protobuf := false
-	switch val := val.(type) {
-	case proto.Message:
-		if r.protobuf {
-			protobuf = true
-			err = proto.Unmarshal(r.Body, val)
-		} else {
-			err = json.Unmarshal(r.Body, val)
-		}
-	default:
-		err = json.Unmarshal(r.Body, val)
+	ctype := r.Response.Header.Get(contentTypeHeader)
+	codec := r.codecs.Codec(ctype, val)
+	if codec == nil {
+		codec = r.codecs.Codec(jsonContentType, val)
 	}
-	if err != nil && !protobuf && r.Response.Header.Get(contentTypeHeader) != jsonContentType {
+	err := codec.Unmarshal(r.Body, val)
+	if err != nil && ctype != jsonContentType {
 		err = fmt.Errorf("%s", r.Body)
 	}
 	return err
@@ -99,17 +90,21 @@ func (r *Response) Decode(val interface{}) error {
 
 type Client struct {
 	log.Sugar
-	Client   *http.Client
-	URL      string
-	auth     Auth
-	protobuf bool
-	status   int
+	Client    *http.Client
+	URL       string
+	auth      Auth
+	codecs    *CodecRegistry
+	preferred string
+	status    int
+	retry     *retryPolicy
+	circuit   *CircuitBreaker
 }
 
 func NewClient(logger log.Logger, timeout time.Duration) *Client {
 	return &Client{
 		Sugar:  log.NewSugar(logger),
 		Client: &http.Client{Timeout: 5 * time.Second},
+		codecs: DefaultCodecs,
 	}
 }
 
@@ -118,11 +113,28 @@ func (c *Client) Auth(auth Auth) *Client {
 	return c
 }
 
-func (c *Client) Protobuf() *Client {
-	c.protobuf = true
+// Codecs returns the CodecRegistry used to marshal requests and unmarshal
+// responses. Register additional codecs on it to support content types
+// beyond the built-ins.
+func (c *Client) Codecs() *CodecRegistry {
+	return c.codecs
+}
+
+// PreferContentType sets the MIME type the client marshals requests as and
+// advertises in the Accept header, e.g. "application/protobuf" or
+// "application/msgpack". The Accept header is sent only on requests that set
+// no Content-Type, i.e. those without a codec-marshaled or json.RawMessage
+// body. It replaces the old Protobuf() toggle now that
+// marshaling is codec-driven rather than hard-coded to JSON/protobuf.
+func (c *Client) PreferContentType(mime string) *Client {
+	c.preferred = mime
 	return c
 }
 
+func (c *Client) breaker() *CircuitBreaker {
+	return c.circuit
+}
+
 func (c *Client) ExpectStatus(status int) *Client {
 	c.status = status
 	return c
@@ -195,17 +207,17 @@ func (c *Client) request(method string, header http.Header, url string, r interf
 			case json.RawMessage:
 				ctype = jsonContentType
 				body = r
-			case proto.Message:
-				if c.protobuf {
-					ctype = protoContentType
-					body, err = proto.Marshal(r)
-				} else {
+			default:
+				ctype = c.preferred
+				if ctype == "" {
 					ctype = jsonContentType
-					body, err = json.Marshal(r)
 				}
-			default:
-				ctype = jsonContentType
-				body, err = json.Marshal(r)
+				codec := c.codecs.Codec(ctype, r)
+				if codec == nil {
+					codec = c.codecs.Codec(jsonContentType, r)
+					ctype = jsonContentType
+				}
+				body, err = codec.Marshal(r)
 			}
 			if err != nil {
 				c.Errorf("Failed to marshal: %s", err.Error())
@@ -213,6 +225,45 @@ func (c *Client) request(method string, header http.Header, url string, r interf
 			}
 		}
 	}
+	breaker := c.breaker()
+	if breaker != nil && !breaker.allow() {
+		c.Errorf("Circuit breaker open for %s", c.URL)
+		return nil, ErrCircuitOpen
+	}
+	policy := c.retry
+	if policy == nil {
+		policy = &retryPolicy{attempts: 1}
+	}
+	var res *Response
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			c.Warningf("Retrying %s %s (attempt %d/%d)", method, url, attempt+1, policy.attempts)
+		}
+		var rtErr error
+		res, rtErr = c.send(method, header, url, ctype, body)
+		if breaker != nil {
+			if defaultRetryIf(res, rtErr) {
+				breaker.recordFailure()
+			} else {
+				breaker.recordSuccess()
+			}
+		}
+		retryIf := policy.retryIf
+		custom := retryIf != nil
+		if retryIf == nil {
+			retryIf = defaultRetryIf
+		}
+		if attempt+1 >= policy.attempts || (!custom && !idempotentMethods[strings.ToUpper(method)]) {
+			return res, rtErr
+		}
+		if !retryIf(res, rtErr) {
+			return res, rtErr
+		}
+		time.Sleep(policy.wait(attempt, res))
+	}
+}
+
+func (c *Client) send(method string, header http.Header, url string, ctype string, body []byte) (*Response, error) {
 	req, err := http.NewRequest(method, c.URL+url, bytes.NewBuffer(body))
 	if err != nil {
 		c.Errorf("Failed to create request: %s", err.Error())
@@ -236,11 +287,11 @@ func (c *Client) request(method string, header http.Header, url string, r interf
 		c.auth = auth
 	}
 	if ctype == "" {
-		if c.protobuf {
-			req.Header.Set("Accept", protoContentType)
-		} else {
-			req.Header.Set("Accept", jsonContentType)
+		accept := c.preferred
+		if accept == "" {
+			accept = jsonContentType
 		}
+		req.Header.Set("Accept", accept)
 	}
 	c.dumpRequest(req, body)
 	res, err := c.Client.Do(req)
@@ -264,7 +315,7 @@ func (c *Client) request(method string, header http.Header, url string, r interf
 		Sugar:    c.Sugar,
 		Response: res,
 		Body:     data,
-		protobuf: c.protobuf,
+		codecs:   c.codecs,
 	}, nil
 }
 
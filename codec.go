@@ -0,0 +1,135 @@
+package rest
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Codec marshals and unmarshals values for a set of content types. Register
+// one with a CodecRegistry to make it available for content negotiation.
+type Codec interface {
+	ContentTypes() []string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Matcher lets a Codec opt out of handling a particular value even though it
+// claims a matching content type, e.g. a jsonpb codec that only wants
+// proto.Message values and leaves everything else to the plain JSON codec.
+type Matcher interface {
+	Supports(v interface{}) bool
+}
+
+// CodecRegistry maps content types to the Codecs that can handle them.
+// Codecs registered later take precedence for a given content type, so
+// callers can shadow a built-in codec (e.g. swap in a custom jsonpb) without
+// forking the package.
+type CodecRegistry struct {
+	mu     sync.RWMutex
+	byType map[string][]Codec
+}
+
+// NewCodecRegistry returns an empty registry.
+func NewCodecRegistry() *CodecRegistry {
+	return &CodecRegistry{byType: make(map[string][]Codec)}
+}
+
+// Register adds codec for each of the content types it declares and returns
+// the registry so calls can be chained.
+func (r *CodecRegistry) Register(codec Codec) *CodecRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, ct := range codec.ContentTypes() {
+		r.byType[ct] = append([]Codec{codec}, r.byType[ct]...)
+	}
+	return r
+}
+
+// Codec returns the best codec registered for contentType that supports v,
+// or nil if none match. contentType may include parameters (e.g.
+// "application/json; charset=utf-8"); only the MIME type is matched.
+func (r *CodecRegistry) Codec(contentType string, v interface{}) Codec {
+	mime := mimeOnly(contentType)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, c := range r.byType[mime] {
+		if m, ok := c.(Matcher); ok && !m.Supports(v) {
+			continue
+		}
+		return c
+	}
+	return nil
+}
+
+// Negotiate parses an Accept header (including q-values) and returns the
+// first codec, along with its content type, that matches both a requested
+// type and v, or nil and "" if none does. Wildcard ranges such as "*/*"
+// match no codec, so an empty or missing Accept header (treated as "*/*")
+// also yields nil.
+func (r *CodecRegistry) Negotiate(accept string, v interface{}) (Codec, string) {
+	for _, mime := range parseAccept(accept) {
+		if mime == "*/*" {
+			continue
+		}
+		if c := r.Codec(mime, v); c != nil {
+			return c, mime
+		}
+	}
+	return nil, ""
+}
+
+func mimeOnly(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.TrimSpace(contentType)
+}
+
+type acceptRange struct {
+	mime string
+	q    float64
+}
+
+// parseAccept returns the MIME types in an Accept header ordered from most
+// to least preferred, honoring q-values (default 1.0).
+func parseAccept(header string) []string {
+	if header == "" {
+		return []string{"*/*"}
+	}
+	ranges := make([]acceptRange, 0, 4)
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		mime := part
+		q := 1.0
+		if i := strings.IndexByte(part, ';'); i >= 0 {
+			mime = strings.TrimSpace(part[:i])
+			for _, param := range strings.Split(part[i+1:], ";") {
+				param = strings.TrimSpace(param)
+				if v, ok := cutPrefix(param, "q="); ok {
+					if f, err := strconv.ParseFloat(v, 64); err == nil {
+						q = f
+					}
+				}
+			}
+		}
+		ranges = append(ranges, acceptRange{mime: mime, q: q})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+	mimes := make([]string, len(ranges))
+	for i, a := range ranges {
+		mimes[i] = a.mime
+	}
+	return mimes
+}
+
+func cutPrefix(s, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return "", false
+	}
+	return s[len(prefix):], true
+}
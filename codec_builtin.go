@@ -0,0 +1,96 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	msgpackContentType = "application/msgpack"
+	cborContentType    = "application/cbor"
+)
+
+// DefaultCodecs is the CodecRegistry used by Client and Server when none is
+// explicitly configured. Register additional codecs on it, or build a
+// private registry with NewCodecRegistry() to avoid affecting other users
+// of the package.
+var DefaultCodecs = NewCodecRegistry().
+	Register(&protobufCodec{}).
+	Register(&msgpackCodec{}).
+	Register(&cborCodec{}).
+	Register(&jsonCodec{})
+
+// jsonCodec handles application/json. proto.Message values are marshaled
+// with protojson instead of encoding/json so enums, oneofs and well-known
+// types round-trip the way a grpc-gateway JSON client would expect.
+type jsonCodec struct{}
+
+func (c *jsonCodec) ContentTypes() []string { return []string{jsonContentType} }
+
+func (c *jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(proto.Message); ok {
+		return protojson.Marshal(m)
+	}
+	return json.Marshal(v)
+}
+
+func (c *jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	if m, ok := v.(proto.Message); ok {
+		return protojson.Unmarshal(data, m)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// protobufCodec handles application/protobuf and application/x-protobuf. It
+// only supports proto.Message values.
+type protobufCodec struct{}
+
+func (c *protobufCodec) ContentTypes() []string { return ProtobufContentTypes }
+
+func (c *protobufCodec) Supports(v interface{}) bool {
+	_, ok := v.(proto.Message)
+	return ok
+}
+
+func (c *protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("rest: %T does not implement proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (c *protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("rest: %T does not implement proto.Message", v)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// msgpackCodec handles application/msgpack.
+type msgpackCodec struct{}
+
+func (c *msgpackCodec) ContentTypes() []string { return []string{msgpackContentType} }
+
+func (c *msgpackCodec) Marshal(v interface{}) ([]byte, error) { return msgpack.Marshal(v) }
+
+func (c *msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+// cborCodec handles application/cbor.
+type cborCodec struct{}
+
+func (c *cborCodec) ContentTypes() []string { return []string{cborContentType} }
+
+func (c *cborCodec) Marshal(v interface{}) ([]byte, error) { return cbor.Marshal(v) }
+
+func (c *cborCodec) Unmarshal(data []byte, v interface{}) error {
+	return cbor.Unmarshal(data, v)
+}
@@ -0,0 +1,123 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ProblemContentType is the media type for RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// HTTPError is a server-side error that Session.Error renders as an RFC
+// 7807 "problem details" response. Extra carries any additional members a
+// handler wants to surface to the client, e.g. With("user_id", id).
+type HTTPError struct {
+	Status   int
+	Type     string
+	Title    string
+	Detail   string
+	Instance string
+	Extra    map[string]interface{}
+}
+
+// NewHTTPError builds an HTTPError for status, using its canonical reason
+// phrase as the Title and msg as the Detail.
+func NewHTTPError(status int, msg string) *HTTPError {
+	return &HTTPError{
+		Status: status,
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Detail: msg,
+	}
+}
+
+// Errorf is NewHTTPError with a formatted Detail.
+func Errorf(status int, format string, args ...interface{}) *HTTPError {
+	return NewHTTPError(status, fmt.Sprintf(format, args...))
+}
+
+// With sets an extra member on the problem details document and returns e
+// so calls can be chained.
+func (e *HTTPError) With(key string, value interface{}) *HTTPError {
+	if e.Extra == nil {
+		e.Extra = make(map[string]interface{})
+	}
+	e.Extra[key] = value
+	return e
+}
+
+func (e *HTTPError) Error() string {
+	if e.Detail != "" {
+		return e.Detail
+	}
+	return e.Title
+}
+
+// MarshalJSON renders e as RFC 7807 problem details, with Extra flattened
+// into top-level members alongside type/title/status/detail/instance.
+func (e *HTTPError) MarshalJSON() ([]byte, error) {
+	doc := make(map[string]interface{}, len(e.Extra)+5)
+	for k, v := range e.Extra {
+		doc[k] = v
+	}
+	doc["status"] = e.Status
+	if e.Type != "" {
+		doc["type"] = e.Type
+	}
+	if e.Title != "" {
+		doc["title"] = e.Title
+	}
+	if e.Detail != "" {
+		doc["detail"] = e.Detail
+	}
+	if e.Instance != "" {
+		doc["instance"] = e.Instance
+	}
+	return json.Marshal(doc)
+}
+
+// Error writes err to the response. An *HTTPError is serialized as RFC 7807
+// problem details, negotiated against the client's Accept header: codecs
+// that can encode an arbitrary value (e.g. msgpack, CBOR) render it as
+// their own structured format, and everything else, including protobuf
+// clients, falls back to application/problem+json, since HTTPError has no
+// protobuf wire form. Any other error falls back to the existing
+// text/plain behavior of Status.
+func (s *Session) Error(err error) {
+	if e, ok := err.(*HTTPError); ok {
+		s.problem(e)
+		return
+	}
+	s.Status(http.StatusInternalServerError, err)
+}
+
+func (s *Session) problem(e *HTTPError) {
+	status := e.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	codec, ctype := s.codecs().Negotiate(s.RequestHeader().Get(Accept), e)
+	if codec == nil {
+		ctype = JsonContentType
+		codec = s.codecs().Codec(ctype, e)
+	}
+	if ctype == JsonContentType {
+		ctype = ProblemContentType
+	}
+	data, err := codec.Marshal(e)
+	if err != nil {
+		s.Errorf("Failed to encode problem details: %s", err.Error())
+		s.writeHeader(status)
+		return
+	}
+	s.ResponseHeader().Set(ContentType, ctype)
+	s.writeHeader(status)
+	s.Debugf("Writing %s: %d bytes", ctype, len(data))
+	_, err = io.Copy(s.ResponseWriter, bytes.NewBuffer(data))
+	if err != nil {
+		s.Errorf("Failed to write problem details: %s", err.Error())
+	}
+}
@@ -0,0 +1,260 @@
+package rest
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Logging logs method, path, status and latency for every request.
+func Logging() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(s *Session) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: s.ResponseWriter}
+			s.ResponseWriter = sw
+			next(s)
+			if sw.status == 0 {
+				sw.status = http.StatusOK
+			}
+			s.Infof("%s %s %d %v", s.Request.Method, s.Request.URL.Path, sw.status, time.Since(start))
+		}
+	}
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Flush passes through to the wrapped ResponseWriter so middleware doesn't
+// break Session.Stream/SSE's use of http.Flusher. It's a no-op if the
+// wrapped writer doesn't support flushing.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Recover turns a panic in a later handler/middleware into a 500
+// application/problem+json response instead of taking down the server. The
+// panic value is logged server-side but never sent to the client, since it
+// may contain internal state (DB errors, paths, nil-deref context).
+func Recover() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(s *Session) {
+			defer func() {
+				if r := recover(); r != nil {
+					s.Errorf("Recovered from panic: %v", r)
+					s.Error(NewHTTPError(http.StatusInternalServerError, "internal server error"))
+				}
+			}()
+			next(s)
+		}
+	}
+}
+
+// Compress gzip- or deflate-encodes the response body when the client's
+// Accept-Encoding header allows it.
+func Compress() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(s *Session) {
+			enc := negotiateEncoding(s.RequestHeader().Get("Accept-Encoding"))
+			if enc == "" {
+				next(s)
+				return
+			}
+			var w io.WriteCloser
+			switch enc {
+			case "gzip":
+				w = gzip.NewWriter(s.ResponseWriter)
+			case "deflate":
+				fw, err := flate.NewWriter(s.ResponseWriter, flate.DefaultCompression)
+				if err != nil {
+					s.Errorf("Failed to create deflate writer: %s", err.Error())
+					next(s)
+					return
+				}
+				w = fw
+			}
+			s.ResponseHeader().Set("Content-Encoding", enc)
+			s.ResponseHeader().Add("Vary", "Accept-Encoding")
+			s.ResponseWriter = &compressWriter{ResponseWriter: s.ResponseWriter, w: w}
+			defer w.Close()
+			next(s)
+		}
+	}
+}
+
+type compressWriter struct {
+	http.ResponseWriter
+	w io.Writer
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	return w.w.Write(p)
+}
+
+// Flush flushes any buffered compressed data and passes the flush through
+// to the wrapped ResponseWriter, so middleware doesn't break
+// Session.Stream/SSE's use of http.Flusher. It's a no-op if the compressor
+// or wrapped writer don't support flushing.
+func (w *compressWriter) Flush() {
+	if f, ok := w.w.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func negotiateEncoding(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		enc := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if enc == "gzip" || enc == "deflate" {
+			return enc
+		}
+	}
+	return ""
+}
+
+// CORSOptions configures CORS. Origins/Methods/Headers accept "*" to allow
+// any value.
+type CORSOptions struct {
+	Origins []string
+	Methods []string
+	Headers []string
+}
+
+// CORS answers preflight OPTIONS requests and sets Access-Control-Allow-*
+// headers according to opts. A route registered with Handle matches only its
+// own method, so preflights reach CORS only on routes registered for OPTIONS.
+func CORS(opts CORSOptions) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(s *Session) {
+			origin := s.RequestHeader().Get("Origin")
+			if origin != "" && matchesAny(origin, opts.Origins) {
+				s.ResponseHeader().Set("Access-Control-Allow-Origin", origin)
+				s.ResponseHeader().Add("Vary", "Origin")
+				if len(opts.Methods) > 0 {
+					s.ResponseHeader().Set("Access-Control-Allow-Methods", strings.Join(opts.Methods, ", "))
+				}
+				if len(opts.Headers) > 0 {
+					s.ResponseHeader().Set("Access-Control-Allow-Headers", strings.Join(opts.Headers, ", "))
+				}
+			}
+			if s.Request.Method == http.MethodOptions {
+				s.writeHeader(http.StatusNoContent)
+				return
+			}
+			next(s)
+		}
+	}
+}
+
+func matchesAny(value string, allowed []string) bool {
+	for _, a := range allowed {
+		if a == "*" || a == value {
+			return true
+		}
+	}
+	return false
+}
+
+// RequestIDHeader is the header request IDs are read from and echoed on.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestID propagates X-Request-ID: it echoes the inbound header, or
+// generates one if absent, storing it on Session.Data for handlers and
+// other middleware to read via Session.RequestID.
+func RequestID() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(s *Session) {
+			id := s.RequestHeader().Get(RequestIDHeader)
+			if id == "" {
+				id = newRequestID()
+			}
+			s.Data[requestIDKey{}] = id
+			s.ResponseHeader().Set(RequestIDHeader, id)
+			next(s)
+		}
+	}
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// RequestID returns the request ID stored by the RequestID middleware, or
+// "" if it isn't installed.
+func (s *Session) RequestID() string {
+	id, _ := s.Data[requestIDKey{}].(string)
+	return id
+}
+
+// RateLimit throttles requests per Session.RemoteHost using a token bucket
+// that refills at rate tokens/second up to burst tokens.
+func RateLimit(rate float64, burst int) Middleware {
+	limiter := &rateLimiter{rate: rate, burst: float64(burst), buckets: make(map[string]*bucket)}
+	return func(next HandlerFunc) HandlerFunc {
+		return func(s *Session) {
+			key := ""
+			if ip, err := s.RemoteHost(); err == nil {
+				key = ip.String()
+			}
+			if !limiter.allow(key) {
+				s.Error(NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded"))
+				return
+			}
+			next(s)
+		}
+	}
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+}
+
+func (l *rateLimiter) allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	b := l.buckets[key]
+	if b == nil {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
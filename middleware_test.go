@@ -0,0 +1,31 @@
+package rest
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+// TestStatusWriterFlush verifies that statusWriter (installed by Logging())
+// still satisfies http.Flusher and forwards Flush to the underlying
+// ResponseWriter, so it doesn't break Session.Stream/SSE when placed ahead
+// of a streaming route.
+func TestStatusWriterFlush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := &statusWriter{ResponseWriter: rec}
+	w.Flush()
+	if !rec.Flushed {
+		t.Fatal("expected Flush to reach the underlying ResponseRecorder")
+	}
+}
+
+// TestCompressWriterFlush verifies that compressWriter (installed by
+// Compress()) still satisfies http.Flusher and forwards Flush to the
+// underlying ResponseWriter.
+func TestCompressWriterFlush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := &compressWriter{ResponseWriter: rec, w: rec}
+	w.Flush()
+	if !rec.Flushed {
+		t.Fatal("expected Flush to reach the underlying ResponseRecorder")
+	}
+}
@@ -0,0 +1,293 @@
+package rest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// OpenAPIDocument builds an OpenAPI 3.0 document describing every route
+// registered via Route, deriving schemas from Go structs (via reflection)
+// and protobuf messages (via their descriptors).
+func (srv *Server) OpenAPIDocument() map[string]interface{} {
+	title := srv.title
+	if title == "" {
+		title = "API"
+	}
+	version := srv.version
+	if version == "" {
+		version = "0.0.0"
+	}
+	paths := make(map[string]interface{})
+	components := make(map[string]interface{})
+	for _, rt := range srv.routes {
+		item, _ := paths[rt.path].(map[string]interface{})
+		if item == nil {
+			item = make(map[string]interface{})
+			paths[rt.path] = item
+		}
+		item[strings.ToLower(rt.method)] = rt.operation(components)
+	}
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]interface{}{"title": title, "version": version},
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": components,
+		},
+	}
+}
+
+func (r *Route) operation(components map[string]interface{}) map[string]interface{} {
+	op := make(map[string]interface{})
+	if len(r.params) > 0 {
+		params := make([]map[string]interface{}, len(r.params))
+		for i, p := range r.params {
+			params[i] = map[string]interface{}{
+				"name":     p.name,
+				"in":       p.in,
+				"required": p.required,
+				"schema":   map[string]interface{}{"type": "string"},
+			}
+		}
+		op["parameters"] = params
+	}
+	if r.body != nil {
+		op["requestBody"] = map[string]interface{}{
+			"content": map[string]interface{}{
+				JsonContentType: map[string]interface{}{"schema": schemaRef(r.body, components)},
+			},
+		}
+	}
+	responses := make(map[string]interface{})
+	for status, v := range r.returns {
+		resp := map[string]interface{}{"description": http.StatusText(status)}
+		if v != nil {
+			resp["content"] = map[string]interface{}{
+				JsonContentType: map[string]interface{}{"schema": schemaRef(v, components)},
+			}
+		}
+		responses[strconv.Itoa(status)] = resp
+	}
+	op["responses"] = responses
+	return op
+}
+
+// OpenAPI registers a GET route at path that serves the generated OpenAPI
+// document as JSON.
+func (srv *Server) OpenAPI(path string) *Server {
+	return srv.Handle(http.MethodGet, path, func(s *Session) {
+		s.Status(http.StatusOK, srv.OpenAPIDocument())
+	})
+}
+
+// SwaggerUI registers a GET route at path that serves a Swagger UI page
+// pointed at specPath (typically the path passed to OpenAPI).
+func (srv *Server) SwaggerUI(path, specPath string) *Server {
+	page := swaggerUIPage(specPath)
+	return srv.Handle(http.MethodGet, path, func(s *Session) {
+		s.ResponseHeader().Set(ContentType, "text/html; charset=utf-8")
+		s.writeHeader(http.StatusOK)
+		_, err := io.WriteString(s.ResponseWriter, page)
+		if err != nil {
+			s.Errorf("Failed to write Swagger UI page: %s", err.Error())
+		}
+	})
+}
+
+func swaggerUIPage(specPath string) string {
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head><title>API Docs</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist/swagger-ui-bundle.js"></script>
+<script>
+window.onload = function() {
+  SwaggerUIBundle({url: %q, dom_id: "#swagger-ui"})
+}
+</script>
+</body>
+</html>
+`, specPath)
+}
+
+// schemaRef returns a $ref to v's schema in components for a struct or
+// protobuf message, registering it (and anything it transitively
+// references) if it isn't there yet. Any other value gets an inline schema.
+func schemaRef(v interface{}, components map[string]interface{}) map[string]interface{} {
+	if m, ok := v.(proto.Message); ok {
+		return protoSchemaRef(m.ProtoReflect().Descriptor(), components)
+	}
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && t.Kind() == reflect.Struct {
+		name := t.Name()
+		if _, ok := components[name]; !ok {
+			components[name] = "" // reserve the name to break reference cycles
+			components[name] = structSchema(t, components)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+	return fieldSchema(t, components)
+}
+
+// fieldSchema builds the schema for a struct field (or, via schemaRef's
+// fallback, a top-level non-struct value), recursing through pointers and
+// slice/array element types so []Pet and []*Pet reference Pet in components
+// the same way a direct Pet field would, instead of falling through to
+// goSchema's bare "object" schema.
+func fieldSchema(ft reflect.Type, components map[string]interface{}) map[string]interface{} {
+	if ft == nil {
+		return goSchema(ft)
+	}
+	for ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	switch ft.Kind() {
+	case reflect.Struct:
+		return schemaRef(reflect.New(ft).Interface(), components)
+	case reflect.Slice, reflect.Array:
+		elem := ft.Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if elem.Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": fieldSchema(ft.Elem(), components)}
+	default:
+		return goSchema(ft)
+	}
+}
+
+func structSchema(t reflect.Type, components map[string]interface{}) map[string]interface{} {
+	props := make(map[string]interface{})
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		omitempty := false
+		if tag := f.Tag.Get("json"); tag != "" {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				if opt == "omitempty" {
+					omitempty = true
+				}
+			}
+		}
+		props[name] = fieldSchema(f.Type, components)
+		if !omitempty {
+			required = append(required, name)
+		}
+	}
+	doc := map[string]interface{}{"type": "object", "properties": props}
+	if len(required) > 0 {
+		doc["required"] = required
+	}
+	return doc
+}
+
+func goSchema(t reflect.Type) map[string]interface{} {
+	if t == nil {
+		return map[string]interface{}{}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": goSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": goSchema(t.Elem())}
+	default:
+		return map[string]interface{}{"type": "object"}
+	}
+}
+
+func protoSchemaRef(desc protoreflect.MessageDescriptor, components map[string]interface{}) map[string]interface{} {
+	name := string(desc.FullName())
+	if _, ok := components[name]; !ok {
+		components[name] = "" // reserve the name to break reference cycles
+		components[name] = protoSchema(desc, components)
+	}
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func protoSchema(desc protoreflect.MessageDescriptor, components map[string]interface{}) map[string]interface{} {
+	props := make(map[string]interface{})
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		f := fields.Get(i)
+		props[f.JSONName()] = protoFieldSchema(f, components)
+	}
+	return map[string]interface{}{"type": "object", "properties": props}
+}
+
+func protoFieldSchema(f protoreflect.FieldDescriptor, components map[string]interface{}) map[string]interface{} {
+	if f.IsMap() {
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": protoFieldSchema(f.MapValue(), components),
+		}
+	}
+	var schema map[string]interface{}
+	switch f.Kind() {
+	case protoreflect.BoolKind:
+		schema = map[string]interface{}{"type": "boolean"}
+	case protoreflect.StringKind:
+		schema = map[string]interface{}{"type": "string"}
+	case protoreflect.BytesKind:
+		schema = map[string]interface{}{"type": "string", "format": "byte"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		schema = map[string]interface{}{"type": "integer"}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		schema = map[string]interface{}{"type": "number"}
+	case protoreflect.EnumKind:
+		values := f.Enum().Values()
+		names := make([]string, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			names[i] = string(values.Get(i).Name())
+		}
+		schema = map[string]interface{}{"type": "string", "enum": names}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		schema = protoSchemaRef(f.Message(), components)
+	default:
+		schema = map[string]interface{}{"type": "object"}
+	}
+	if f.IsList() {
+		return map[string]interface{}{"type": "array", "items": schema}
+	}
+	return schema
+}
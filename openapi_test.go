@@ -0,0 +1,41 @@
+package rest
+
+import "testing"
+
+type openAPITestPet struct {
+	Name string `json:"name"`
+}
+
+type openAPITestOwner struct {
+	Pets    []openAPITestPet  `json:"pets"`
+	Sitters []*openAPITestPet `json:"sitters"`
+}
+
+// TestSchemaRefSliceOfStruct verifies that slice-of-struct and
+// slice-of-pointer-to-struct fields $ref their element type in components,
+// instead of falling through to a bare {"type":"object"}.
+func TestSchemaRefSliceOfStruct(t *testing.T) {
+	components := make(map[string]interface{})
+	schemaRef(&openAPITestOwner{}, components)
+
+	owner, ok := components["openAPITestOwner"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected openAPITestOwner in components, got %#v", components)
+	}
+	props := owner["properties"].(map[string]interface{})
+
+	for _, field := range []string{"pets", "sitters"} {
+		arr, ok := props[field].(map[string]interface{})
+		if !ok || arr["type"] != "array" {
+			t.Fatalf("expected %s to be an array schema, got %#v", field, props[field])
+		}
+		items, ok := arr["items"].(map[string]interface{})
+		if !ok || items["$ref"] != "#/components/schemas/openAPITestPet" {
+			t.Fatalf("expected %s items to $ref openAPITestPet, got %#v", field, arr["items"])
+		}
+	}
+
+	if _, ok := components["openAPITestPet"]; !ok {
+		t.Fatal("expected openAPITestPet to be registered in components")
+	}
+}
@@ -0,0 +1,165 @@
+package rest
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by Client.Request when a CircuitBreaker has
+// tripped for the client's host and is refusing new attempts.
+var ErrCircuitOpen = errors.New("rest: circuit breaker open")
+
+var idempotentMethods = map[string]bool{
+	"GET":     true,
+	"HEAD":    true,
+	"PUT":     true,
+	"DELETE":  true,
+	"OPTIONS": true,
+}
+
+type retryPolicy struct {
+	attempts   int
+	base       time.Duration
+	max        time.Duration
+	multiplier float64
+	retryIf    func(*Response, error) bool
+}
+
+// wait computes the backoff before the next attempt, honoring Retry-After
+// on 429/503 responses and otherwise using full-jitter exponential backoff.
+func (p *retryPolicy) wait(attempt int, res *Response) time.Duration {
+	if res != nil && (res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusServiceUnavailable) {
+		if d, ok := retryAfter(res.Response); ok {
+			return d
+		}
+	}
+	backoff := float64(p.base) * math.Pow(p.multiplier, float64(attempt))
+	if max := float64(p.max); backoff > max {
+		backoff = max
+	}
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	v := res.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if when, err := http.ParseTime(v); err == nil {
+		return time.Until(when), true
+	}
+	return 0, false
+}
+
+// defaultRetryIf retries on network errors and 5xx/429 responses; it's
+// combined with an idempotent-method check by Client.request so unsafe
+// methods are never retried unless the caller opts in via RetryIf.
+func defaultRetryIf(res *Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	if res == nil {
+		return false
+	}
+	return res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests
+}
+
+// RetryOption configures the policy installed by Client.Retry.
+type RetryOption func(*retryPolicy)
+
+// RetryBackoff sets the exponential backoff parameters: the delay doubles
+// (times multiplier) after each attempt, capped at max, with full jitter
+// applied on top.
+func RetryBackoff(base, max time.Duration, multiplier float64) RetryOption {
+	return func(p *retryPolicy) {
+		p.base = base
+		p.max = max
+		p.multiplier = multiplier
+	}
+}
+
+// RetryIf overrides which responses/errors are considered retryable.
+// Without it, only idempotent methods (GET/HEAD/PUT/DELETE/OPTIONS) are
+// retried; setting it opts every method in, so it can also be used to
+// widen retries to POST/PATCH/etc.
+func RetryIf(fn func(*Response, error) bool) RetryOption {
+	return func(p *retryPolicy) {
+		p.retryIf = fn
+	}
+}
+
+// Retry makes the client retry idempotent requests up to attempts times
+// with exponential backoff and jitter, honoring Retry-After on 429/503
+// responses. attempts counts the initial try, so Retry(1) disables retries.
+func (c *Client) Retry(attempts int, opts ...RetryOption) *Client {
+	p := &retryPolicy{
+		attempts:   attempts,
+		base:       100 * time.Millisecond,
+		max:        5 * time.Second,
+		multiplier: 2,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	c.retry = p
+	return c
+}
+
+// CircuitBreaker tracks consecutive failures for a single upstream and
+// trips into an open state that short-circuits requests once
+// failureThreshold is reached, for cooldown.
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	failureThreshold int
+	cooldown         time.Duration
+	failures         int
+	openUntil        time.Time
+}
+
+// NewCircuitBreaker builds a CircuitBreaker that opens after
+// failureThreshold consecutive failures and stays open for cooldown.
+func NewCircuitBreaker(failureThreshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{failureThreshold: failureThreshold, cooldown: cooldown}
+}
+
+func (cb *CircuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.openUntil.IsZero() {
+		return true
+	}
+	return !time.Now().Before(cb.openUntil)
+}
+
+func (cb *CircuitBreaker) recordSuccess() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.failures = 0
+	cb.openUntil = time.Time{}
+}
+
+func (cb *CircuitBreaker) recordFailure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.failures++
+	if cb.failures >= cb.failureThreshold {
+		cb.openUntil = time.Now().Add(cb.cooldown)
+	}
+}
+
+// CircuitBreaker installs a per-client circuit breaker that trips after
+// failureThreshold consecutive request failures (request errors, including a
+// mismatched status when Client.ExpectStatus is set, and 5xx/429 responses)
+// and stays open for cooldown before allowing another attempt through.
+func (c *Client) CircuitBreaker(failureThreshold int, cooldown time.Duration) *Client {
+	c.circuit = NewCircuitBreaker(failureThreshold, cooldown)
+	return c
+}
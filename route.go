@@ -0,0 +1,62 @@
+package rest
+
+// param describes a path or query parameter captured for OpenAPI generation.
+type param struct {
+	name     string
+	in       string
+	required bool
+}
+
+// Route builds a documented route: its path/query parameters, request
+// body type and per-status response types are captured up front so
+// Server.OpenAPIDocument can generate a spec for it without any further
+// annotation. Start one with Server.Route and finish with Handle.
+type Route struct {
+	srv     *Server
+	method  string
+	path    string
+	params  []param
+	body    interface{}
+	returns map[int]interface{}
+}
+
+// Route starts building a documented route for method and path, e.g.
+// srv.Route("POST", "/users/{id}").
+func (srv *Server) Route(method, path string) *Route {
+	return &Route{srv: srv, method: method, path: path, returns: make(map[int]interface{})}
+}
+
+// PathParam documents a required path parameter, e.g. the "{id}" in
+// "/users/{id}".
+func (r *Route) PathParam(name string) *Route {
+	r.params = append(r.params, param{name: name, in: "path", required: true})
+	return r
+}
+
+// QueryParam documents a query string parameter.
+func (r *Route) QueryParam(name string, required bool) *Route {
+	r.params = append(r.params, param{name: name, in: "query", required: required})
+	return r
+}
+
+// Body documents the request body type. v is only used for its type, e.g.
+// Body(&User{}); it's never invoked.
+func (r *Route) Body(v interface{}) *Route {
+	r.body = v
+	return r
+}
+
+// Returns documents the response type for status. A nil v documents a
+// bodyless response, e.g. Returns(404, nil).
+func (r *Route) Returns(status int, v interface{}) *Route {
+	r.returns[status] = v
+	return r
+}
+
+// Handle finishes the route, registering h (plus mw) with the server the
+// same way Server.Handle would, and records the route for OpenAPI
+// generation.
+func (r *Route) Handle(h HandlerFunc, mw ...Middleware) *Server {
+	r.srv.routes = append(r.srv.routes, r)
+	return r.srv.Handle(r.method, r.path, h, mw...)
+}
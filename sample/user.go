@@ -18,7 +18,7 @@ func UserHandler(s *rest.Session) {
 	case "GET":
 		user := users[id]
 		if user == nil {
-			_ = s.Status(404, nil)
+			s.Error(rest.NewHTTPError(404, "user not found").With("user_id", id))
 			return
 		}
 		_ = s.Status(200, user)
@@ -0,0 +1,117 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/crosstalkio/log"
+	"github.com/gorilla/mux"
+)
+
+// HandlerFunc handles a single request/response exchange.
+type HandlerFunc func(*Session)
+
+// Middleware wraps a HandlerFunc to add cross-cutting behavior (logging,
+// recovery, compression, ...). Middlewares compose outside-in: the first
+// one passed to Use/Handle runs first and sees the response last.
+type Middleware func(HandlerFunc) HandlerFunc
+
+type route struct {
+	handler    HandlerFunc
+	middleware []Middleware
+}
+
+// Server dispatches HTTP requests to HandlerFuncs registered with Handle,
+// running them through any middleware installed with Use plus whatever was
+// passed to Handle itself.
+type Server struct {
+	log.Sugar
+	router     *mux.Router
+	jsonPrefix string
+	jsonIndent string
+	codecs     *CodecRegistry
+	middleware []Middleware
+	routes     []*Route
+	title      string
+	version    string
+}
+
+// OpenAPIInfo sets the title/version reported in the generated OpenAPI
+// document's info object. Defaults to "API"/"0.0.0" if never called.
+func (srv *Server) OpenAPIInfo(title, version string) *Server {
+	srv.title = title
+	srv.version = version
+	return srv
+}
+
+// NewServer returns a Server with an empty gorilla/mux router and the
+// default codec registry.
+func NewServer(logger log.Logger) *Server {
+	return &Server{
+		Sugar:  log.NewSugar(logger),
+		router: mux.NewRouter(),
+		codecs: DefaultCodecs,
+	}
+}
+
+// JSONIndent sets the prefix/indent used when encoding JSON responses, as
+// with json.MarshalIndent.
+func (srv *Server) JSONIndent(prefix, indent string) *Server {
+	srv.jsonPrefix = prefix
+	srv.jsonIndent = indent
+	return srv
+}
+
+// Codecs returns the CodecRegistry used to decode requests and encode
+// responses. Register additional codecs on it to support content types
+// beyond the built-ins.
+func (srv *Server) Codecs() *CodecRegistry {
+	return srv.codecs
+}
+
+// Router returns the underlying gorilla/mux router, for callers that need
+// to register routes it doesn't have a HandlerFunc-based equivalent for.
+func (srv *Server) Router() *mux.Router {
+	return srv.router
+}
+
+// Use installs middleware that wraps every route, in addition to whatever
+// is passed directly to Handle. Middleware installed here applies
+// regardless of whether Use is called before or after the routes it
+// affects are registered.
+func (srv *Server) Use(mw ...Middleware) *Server {
+	srv.middleware = append(srv.middleware, mw...)
+	return srv
+}
+
+// Handle registers h to serve method requests to path, wrapped by mw (run
+// innermost, closest to h) and then by whatever was installed with Use (run
+// outermost).
+func (srv *Server) Handle(method, path string, h HandlerFunc, mw ...Middleware) *Server {
+	rt := &route{handler: h, middleware: mw}
+	srv.router.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		srv.dispatch(rt, w, r)
+	}).Methods(method)
+	return srv
+}
+
+func (srv *Server) dispatch(rt *route, w http.ResponseWriter, r *http.Request) {
+	s := &Session{
+		Context:        log.NewContext(r.Context(), srv.Sugar),
+		server:         srv,
+		Data:           make(map[interface{}]interface{}),
+		Request:        r,
+		ResponseWriter: w,
+	}
+	h := rt.handler
+	for i := len(rt.middleware) - 1; i >= 0; i-- {
+		h = rt.middleware[i](h)
+	}
+	for i := len(srv.middleware) - 1; i >= 0; i-- {
+		h = srv.middleware[i](h)
+	}
+	h(s)
+}
+
+func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	srv.router.ServeHTTP(w, r)
+}
@@ -63,47 +63,67 @@ func (s *Session) ResponseHeader() http.Header {
 }
 
 func (s *Session) Decode(val interface{}) error {
-	switch v := val.(type) {
-	case proto.Message:
-		if isProto(contentType(s.Request)) ||
-			(s.Request.ContentLength <= 0 && isProto(accept(s.Request))) {
-			data, err := ioutil.ReadAll(s.Request.Body)
-			if err != nil {
-				s.Errorf("Failed to read request body: %s", err.Error())
-				return err
-			}
-			err = proto.Unmarshal(data, v)
-			if err != nil {
-				s.Errorf("Failed to unmarshal proto request body: %s", err.Error())
-			}
-			return err
-		} else {
-			err := json.NewDecoder(s.Request.Body).Decode(v)
-			if err != nil {
-				s.Errorf("Failed to decode JSON request body: %s", err.Error())
-				return err
-			}
-			// to check 'required' props of proto2
-			_, err = proto.Marshal(v)
-			return err
-		}
-	default:
-		return json.NewDecoder(s.Request.Body).Decode(v)
+	ctype := contentType(s.Request)
+	if ctype == "" && s.Request.ContentLength <= 0 {
+		ctype = accept(s.Request)
+	}
+	codec := s.codecs().Codec(ctype, val)
+	if codec == nil {
+		codec = s.codecs().Codec(JsonContentType, val)
+	}
+	data, err := ioutil.ReadAll(s.Request.Body)
+	if err != nil {
+		s.Errorf("Failed to read request body: %s", err.Error())
+		return err
+	}
+	err = codec.Unmarshal(data, val)
+	if err != nil {
+		s.Errorf("Failed to decode request body: %s", err.Error())
+		return err
+	}
+	if v, ok := val.(proto.Message); ok {
+		// to check 'required' props of proto2
+		_, err = proto.Marshal(v)
 	}
+	return err
 }
 
 func (s *Session) encode(status int, val interface{}) error {
-	switch v := val.(type) {
-	case proto.Message:
-		accept := accepts(ProtobufContentTypes, s.RequestHeader()[Accept])
-		if isProto(contentType(s.Request)) || accept != "" {
-			return s.encodeProto(status, v, accept)
-		} else {
-			return s.encodeJSON(status, v)
-		}
-	default:
-		return s.encodeJSON(status, v)
+	codec, ctype := s.codecs().Negotiate(s.RequestHeader().Get(Accept), val)
+	if codec == nil {
+		ctype = JsonContentType
+		codec = s.codecs().Codec(ctype, val)
+	}
+	var data []byte
+	var err error
+	if ctype == JsonContentType && (s.server.jsonPrefix != "" || s.server.jsonIndent != "") {
+		data, err = json.MarshalIndent(val, s.server.jsonPrefix, s.server.jsonIndent)
+	} else {
+		data, err = codec.Marshal(val)
 	}
+	if err != nil {
+		s.Errorf("Failed to encode %s: %s", ctype, err.Error())
+		return err
+	}
+	s.ResponseHeader().Set(ContentType, ctype)
+	s.writeHeader(status)
+	s.Debugf("Writing %s: %d bytes", ctype, len(data))
+	_, err = io.Copy(s.ResponseWriter, bytes.NewBuffer(data))
+	if err != nil {
+		s.Errorf("Failed to write body: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// codecs returns the CodecRegistry the session's server is configured with,
+// falling back to DefaultCodecs so Session still works outside a Server
+// (e.g. in tests that construct one by hand).
+func (s *Session) codecs() *CodecRegistry {
+	if s.server != nil && s.server.codecs != nil {
+		return s.server.codecs
+	}
+	return DefaultCodecs
 }
 
 func (s *Session) Status(status int, v interface{}) {
@@ -162,50 +182,6 @@ func (s *Session) writeHeader(status int) {
 	s.ResponseWriter.WriteHeader(status)
 }
 
-func (s *Session) encodeProto(status int, v proto.Message, accept string) error {
-	if accept == "" {
-		accept = ProtobufContentTypes[0]
-	}
-	s.ResponseHeader().Set(ContentType, accept)
-	data, err := proto.Marshal(v)
-	if err != nil {
-		s.Errorf("Failed to encode protobuf: %s", err.Error())
-		return err
-	}
-	s.writeHeader(status)
-	s.Debugf("Writing protobuf: %d bytes", len(data))
-	_, err = io.Copy(s.ResponseWriter, bytes.NewBuffer(data))
-	if err != nil {
-		s.Errorf("Failed to write protobuf: %s", err.Error())
-		return err
-	}
-	return nil
-}
-
-func (s *Session) encodeJSON(status int, v interface{}) error {
-	// s.Debugf("encoing: %v", v)
-	s.ResponseHeader().Set(ContentType, JsonContentType)
-	var data []byte
-	var err error
-	if s.server.jsonPrefix != "" || s.server.jsonIndent != "" {
-		data, err = json.MarshalIndent(v, s.server.jsonPrefix, s.server.jsonIndent)
-	} else {
-		data, err = json.Marshal(v)
-	}
-	if err != nil {
-		s.Errorf("Failed to encode JSON: %s", err.Error())
-		return err
-	}
-	s.writeHeader(status)
-	s.Debugf("Writing JSON: %d bytes", len(data))
-	_, err = s.ResponseWriter.Write(data)
-	if err != nil {
-		s.Errorf("Failed to write JSON: %s", err.Error())
-		return err
-	}
-	return nil
-}
-
 func accept(r *http.Request) string {
 	return r.Header.Get(Accept)
 }
@@ -213,27 +189,3 @@ func accept(r *http.Request) string {
 func contentType(r *http.Request) string {
 	return r.Header.Get(ContentType)
 }
-
-func isProto(mime string) bool {
-	return isTypeOf(mime, ProtobufContentTypes)
-}
-
-func isTypeOf(mime string, types []string) bool {
-	for _, t := range types {
-		if strings.HasPrefix(mime, t) {
-			return true
-		}
-	}
-	return false
-}
-
-func accepts(types []string, accepts []string) string {
-	for _, t := range types {
-		for _, a := range accepts {
-			if strings.HasPrefix(a, t) {
-				return t
-			}
-		}
-	}
-	return ""
-}
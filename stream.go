@@ -0,0 +1,247 @@
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Event is a single Server-Sent Event frame written by Session.SSE.
+type Event struct {
+	ID    string
+	Event string
+	Data  interface{}
+}
+
+// Stream writes status then each value received from ch as a separate
+// frame, flushing after every one so subscribers see them as they arrive.
+// Clients that accept application/protobuf get length-prefixed protobuf
+// frames (values must implement proto.Message); everyone else gets
+// newline-delimited JSON. Stream returns when ch is closed or the request
+// context is cancelled.
+func (s *Session) Stream(status int, ch <-chan interface{}) error {
+	framed := acceptsProtobuf(s.RequestHeader().Get(Accept))
+	ctype := JsonContentType
+	if framed {
+		ctype = ProtobufContentTypes[0]
+	}
+	s.ResponseHeader().Set(ContentType, ctype)
+	s.writeHeader(status)
+	flusher, _ := s.ResponseWriter.(http.Flusher)
+	ctx := s.Request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case v, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := s.writeFrame(v, framed); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+func (s *Session) writeFrame(v interface{}, framed bool) error {
+	if !framed {
+		data, err := json.Marshal(v)
+		if err != nil {
+			s.Errorf("Failed to marshal stream frame: %s", err.Error())
+			return err
+		}
+		data = append(data, '\n')
+		if _, err := s.ResponseWriter.Write(data); err != nil {
+			s.Errorf("Failed to write stream frame: %s", err.Error())
+			return err
+		}
+		return nil
+	}
+	m, ok := v.(proto.Message)
+	if !ok {
+		err := fmt.Errorf("rest: %T does not implement proto.Message", v)
+		s.Errorf("Failed to marshal stream frame: %s", err.Error())
+		return err
+	}
+	data, err := proto.Marshal(m)
+	if err != nil {
+		s.Errorf("Failed to marshal stream frame: %s", err.Error())
+		return err
+	}
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(data)))
+	if _, err := s.ResponseWriter.Write(size[:]); err != nil {
+		s.Errorf("Failed to write stream frame length: %s", err.Error())
+		return err
+	}
+	if _, err := s.ResponseWriter.Write(data); err != nil {
+		s.Errorf("Failed to write stream frame: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// SSE writes status then streams each Event received from ch as a
+// text/event-stream frame, flushing after every one. It returns when ch is
+// closed or the request context is cancelled, and errors if the
+// ResponseWriter doesn't support flushing.
+func (s *Session) SSE(status int, ch <-chan Event) error {
+	flusher, ok := s.ResponseWriter.(http.Flusher)
+	if !ok {
+		return fmt.Errorf("rest: ResponseWriter does not support flushing")
+	}
+	s.ResponseHeader().Set(ContentType, "text/event-stream")
+	s.ResponseHeader().Set("Cache-Control", "no-cache")
+	s.ResponseHeader().Set("Connection", "keep-alive")
+	s.writeHeader(status)
+	ctx := s.Request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := s.writeEvent(e); err != nil {
+				return err
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Session) writeEvent(e Event) error {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		s.Errorf("Failed to marshal SSE event: %s", err.Error())
+		return err
+	}
+	var buf bytes.Buffer
+	if e.ID != "" {
+		fmt.Fprintf(&buf, "id: %s\n", e.ID)
+	}
+	if e.Event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", e.Event)
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		fmt.Fprintf(&buf, "data: %s\n", line)
+	}
+	buf.WriteByte('\n')
+	if _, err := s.ResponseWriter.Write(buf.Bytes()); err != nil {
+		s.Errorf("Failed to write SSE event: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+func acceptsProtobuf(accept string) bool {
+	for _, t := range ProtobufContentTypes {
+		if strings.Contains(accept, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Stream opens method/url and returns the Response with its body unread,
+// unlike Request which buffers the whole body up front; read it frame by
+// frame with Response.Stream or Response.SSE. The http.Client's Timeout
+// still applies to the whole exchange, body included, so a stream is cut
+// off once it elapses.
+func (c *Client) Stream(method, url string) (*Response, error) {
+	req, err := http.NewRequest(method, c.URL+url, nil)
+	if err != nil {
+		c.Errorf("Failed to create request: %s", err.Error())
+		return nil, err
+	}
+	req.Header.Set("Accept", jsonContentType)
+	if c.auth != nil {
+		if err := c.auth.Authorize(req); err != nil {
+			c.Errorf("Failed to authorize: %s", err.Error())
+			return nil, err
+		}
+	}
+	res, err := c.Client.Do(req)
+	if err != nil {
+		c.Errorf("Failed to make request: %s", err.Error())
+		return nil, err
+	}
+	return &Response{Sugar: c.Sugar, Response: res, codecs: c.codecs}, nil
+}
+
+// Stream reads r's body as newline-delimited JSON, calling fn with each
+// line until the body is exhausted, fn returns an error, or the connection
+// is closed. It always closes the body before returning.
+func (r *Response) Stream(fn func(msg json.RawMessage) error) error {
+	defer r.Response.Body.Close()
+	scanner := bufio.NewScanner(r.Response.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if err := fn(json.RawMessage(append([]byte(nil), line...))); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// SSEEvent is a single Server-Sent Event frame as read back by
+// Response.SSE.
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  json.RawMessage
+}
+
+// SSE reads r's body as a text/event-stream, calling fn with each event
+// until the stream ends or fn returns an error. It always closes the body
+// before returning.
+func (r *Response) SSE(fn func(event SSEEvent) error) error {
+	defer r.Response.Body.Close()
+	reader := bufio.NewReader(r.Response.Body)
+	var cur SSEEvent
+	var data bytes.Buffer
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "":
+			if data.Len() > 0 {
+				cur.Data = json.RawMessage(append([]byte(nil), bytes.TrimSuffix(data.Bytes(), []byte("\n"))...))
+				if ferr := fn(cur); ferr != nil {
+					return ferr
+				}
+				cur = SSEEvent{}
+				data.Reset()
+			}
+		case strings.HasPrefix(line, "id:"):
+			cur.ID = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "event:"):
+			cur.Event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data.WriteString(strings.TrimPrefix(line, "data:"))
+			data.WriteByte('\n')
+		}
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}